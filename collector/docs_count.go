@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus-community/elasticsearch_exporter/pkg/clusterinfo"
@@ -15,7 +18,98 @@ import (
 
 type IndexDocs struct {
 	Index string `json:"index"`
+	UUID  string `json:"uuid"`
 	Count string `json:"docs.count"`
+	// StoreSize is the store.size cat column, in bytes: the "bytes=b" query
+	// parameter is required for this to be a plain byte count rather than
+	// store.size's default human-readable form ("10.5kb").
+	StoreSize string `json:"store.size"`
+}
+
+// ShardLocation pins an index's primary shard to the node currently hosting
+// it, as reported by /_cat/shards. It is used to attach exemplars to
+// per-index metrics so operators can jump from a metric spike straight to
+// the shard/node responsible for it.
+type ShardLocation struct {
+	Shard string `json:"shard"`
+	Node  string `json:"node"`
+}
+
+// indexPatternSet classifies a list of -es.docs-count.indices /
+// -es.docs-count.exclude-indices patterns into literal names, matched by
+// exact string, and shell-glob/regex patterns, compiled once at
+// construction time so Collect only ever does cheap lookups/matches.
+//
+// A pattern is treated as:
+//   - a regex, if it is wrapped in leading/trailing slashes, e.g. "/^logs-.*$/"
+//   - a glob, if it contains "*" or "?"
+//   - a literal, otherwise
+type indexPatternSet struct {
+	literals map[string]bool
+	regexes  []*regexp.Regexp
+}
+
+func newIndexPatternSet(patterns []string) (*indexPatternSet, error) {
+	set := &indexPatternSet{literals: make(map[string]bool)}
+	for _, p := range patterns {
+		switch {
+		case strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") && len(p) >= 2:
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index regex %q: %w", p, err)
+			}
+			set.regexes = append(set.regexes, re)
+		case strings.ContainsAny(p, "*?"):
+			re, err := regexp.Compile(globToRegexp(p))
+			if err != nil {
+				return nil, fmt.Errorf("invalid index glob %q: %w", p, err)
+			}
+			set.regexes = append(set.regexes, re)
+		default:
+			set.literals[p] = true
+		}
+	}
+	return set, nil
+}
+
+// empty reports whether no patterns were configured, i.e. "match nothing
+// specific" rather than "match nothing at all".
+func (s *indexPatternSet) empty() bool {
+	return s == nil || (len(s.literals) == 0 && len(s.regexes) == 0)
+}
+
+func (s *indexPatternSet) matches(index string) bool {
+	if s == nil {
+		return false
+	}
+	if s.literals[index] {
+		return true
+	}
+	for _, re := range s.regexes {
+		if re.MatchString(index) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp translates a shell-style glob (only "*" and "?" are special)
+// into an anchored regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
 }
 
 type DocsCount struct {
@@ -24,42 +118,77 @@ type DocsCount struct {
 	url             *url.URL
 	clusterInfoCh   chan *clusterinfo.Response
 	lastClusterInfo *clusterinfo.Response
-	includedIndices map[string]bool
+	includedIndices *indexPatternSet
+	excludedIndices *indexPatternSet
+
+	docsCountDesc     *prometheus.Desc
+	jsonParseFailures prometheus.Counter
 
-	metrics             *prometheus.GaugeVec
-	jsonParseFailures   prometheus.Counter
+	docsCountDistribution *prometheus.HistogramVec
+	storeSizeDistribution *prometheus.HistogramVec
+}
+
+// newDistributionHistogram builds a native (sparse) histogram with no
+// classic buckets. Native histograms keep cardinality flat no matter how
+// many indices exist, at the cost of losing the plain gauge's per-index
+// label, which is why this complements rather than replaces docsCountDesc.
+func newDistributionHistogram(name, help string) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:                            prometheus.BuildFQName(namespace, "index", name),
+			Help:                            help,
+			Buckets:                         []float64{},
+			NativeHistogramBucketFactor:     1.1,
+			NativeHistogramMaxBucketNumber:  160,
+			NativeHistogramMinResetDuration: time.Hour,
+		},
+		[]string{"cluster"},
+	)
 }
 
 /*
 接收指定的索引集合 includedIndices []string, 如未指定，则采集全部索引；
+支持字面量、shell glob（*、?）与正则（/re/）三种匹配方式；
+excludedIndices 采用同样的语义，用于在 included 基础上排除部分索引；
 支持 Prometheus 注册与 clusterinfo 通信
-按索引维度打文档数指标
+按索引维度打文档数指标，并额外维护按 cluster 聚合的 docs_count/store_size 原生直方图
 加入 cluster 维度，兼容多集群部署
 */
 
-func NewDocsCount(logger *slog.Logger, client *http.Client, url *url.URL, included []string) *DocsCount {
-	includeMap := make(map[string]bool)
-	for _, idx := range included {
-		includeMap[idx] = true
+func NewDocsCount(logger *slog.Logger, client *http.Client, url *url.URL, included []string, excluded []string) (*DocsCount, error) {
+	includeSet, err := newIndexPatternSet(included)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse included indices patterns: %w", err)
+	}
+	excludeSet, err := newIndexPatternSet(excluded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse excluded indices patterns: %w", err)
 	}
 
 	d := &DocsCount{
 		logger: logger,
 		client: client,
 		url:    url,
-		includedIndices: includeMap,
-
-		metrics: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name: prometheus.BuildFQName(namespace, "index", "docs_count"),
-				Help: "Number of documents per index.",
-			},
-			[]string{"index", "cluster"},
+		includedIndices: includeSet,
+		excludedIndices: excludeSet,
+
+		docsCountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "docs_count"),
+			"Number of documents per index.",
+			[]string{"index", "cluster"}, nil,
 		),
 		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: prometheus.BuildFQName(namespace, "index", "json_parse_failures"),
 			Help: "Number of JSON parse failures while collecting docs count.",
 		}),
+		docsCountDistribution: newDistributionHistogram(
+			"docs_count_distribution",
+			"Native histogram of the per-index document count distribution across the cluster.",
+		),
+		storeSizeDistribution: newDistributionHistogram(
+			"store_size_bytes_distribution",
+			"Native histogram of the per-index store size (bytes) distribution across the cluster.",
+		),
 		clusterInfoCh: make(chan *clusterinfo.Response),
 		lastClusterInfo: &clusterinfo.Response{
 			ClusterName: "unknown_cluster",
@@ -77,7 +206,7 @@ func NewDocsCount(logger *slog.Logger, client *http.Client, url *url.URL, includ
 		logger.Debug("exiting cluster info receive loop")
 	}()
 
-	return d
+	return d, nil
 }
 
 func (d *DocsCount) ClusterLabelUpdates() *chan *clusterinfo.Response {
@@ -89,8 +218,65 @@ func (d *DocsCount) String() string {
 }
 
 func (d *DocsCount) Describe(ch chan<- *prometheus.Desc) {
-	d.metrics.Describe(ch)
+	ch <- d.docsCountDesc
 	ch <- d.jsonParseFailures.Desc()
+	d.docsCountDistribution.Describe(ch)
+	d.storeSizeDistribution.Describe(ch)
+}
+
+// fetchPrimaryShardLocations queries /_cat/shards and returns, for every
+// index, the node currently hosting its primary shard. It is used to enrich
+// per-index metrics with exemplars pointing at the responsible shard/node.
+func (d *DocsCount) fetchPrimaryShardLocations() (map[string]ShardLocation, error) {
+	u := *d.url
+	u.Path = path.Join(u.Path, "/_cat/shards")
+	q := u.Query()
+	q.Set("format", "json")
+	q.Set("h", "index,shard,node,prirep")
+	u.RawQuery = q.Encode()
+
+	resp, err := d.client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shard locations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("non-200 response fetching shard locations: %d", resp.StatusCode)
+	}
+
+	var rows []struct {
+		Index  string `json:"index"`
+		Shard  string `json:"shard"`
+		Node   string `json:"node"`
+		Prirep string `json:"prirep"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to parse shard locations JSON: %w", err)
+	}
+
+	locations := make(map[string]ShardLocation, len(rows))
+	for _, row := range rows {
+		if row.Prirep != "p" {
+			continue
+		}
+		locations[row.Index] = ShardLocation{Shard: row.Shard, Node: row.Node}
+	}
+	return locations, nil
+}
+
+// observeWithExemplar records value on the given native histogram, attaching
+// exemplarLabels to the observation when non-empty. Every prometheus.Observer
+// returned by a HistogramVec also implements prometheus.ExemplarObserver, so
+// this always succeeds; the type assertion exists only because Observer
+// itself doesn't expose ObserveWithExemplar.
+func (d *DocsCount) observeWithExemplar(histogram *prometheus.HistogramVec, cluster string, value float64, exemplarLabels prometheus.Labels) {
+	observer := histogram.WithLabelValues(cluster)
+	if len(exemplarLabels) == 0 {
+		observer.Observe(value)
+		return
+	}
+	observer.(prometheus.ExemplarObserver).ObserveWithExemplar(value, exemplarLabels)
 }
 
 func (d *DocsCount) Collect(ch chan<- prometheus.Metric) {
@@ -102,7 +288,8 @@ func (d *DocsCount) Collect(ch chan<- prometheus.Metric) {
 	u.Path = path.Join(u.Path, "/_cat/indices")
 	q := u.Query()
 	q.Set("format", "json")
-	q.Set("h", "index,docs.count")
+	q.Set("h", "index,docs.count,uuid,store.size")
+	q.Set("bytes", "b")
 	u.RawQuery = q.Encode()
 
 	resp, err := d.client.Get(u.String())
@@ -124,15 +311,51 @@ func (d *DocsCount) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	shardLocations, err := d.fetchPrimaryShardLocations()
+	if err != nil {
+		d.logger.Warn("failed to fetch shard locations, exemplars will be omitted", "err", err)
+		shardLocations = nil
+	}
+
+	// Metrics are built fresh from this scrape's data rather than kept in a
+	// long-lived vector, so an index that disappears between scrapes simply
+	// stops being emitted on its own; there is no stale series to delete.
 	for _, idx := range data {
-		if len(d.includedIndices) > 0 && !d.includedIndices[idx.Index] {
+		if !d.includedIndices.empty() && !d.includedIndices.matches(idx.Index) {
+			continue
+		}
+		if d.excludedIndices.matches(idx.Index) {
 			continue
 		}
 		cnt, err := strconv.ParseFloat(idx.Count, 64)
 		if err != nil {
 			continue
 		}
-		d.metrics.WithLabelValues(idx.Index, d.lastClusterInfo.ClusterName).Set(cnt)
+
+		// The OpenMetrics text encoder only ever writes exemplars on counter
+		// and histogram-bucket samples, never on gauges, so attaching one to
+		// the per-index docs_count gauge below would silently be dropped at
+		// exposition. The native histograms observed here are where
+		// exemplars actually make it to the scraper.
+		exemplarLabels := prometheus.Labels{}
+		if idx.UUID != "" {
+			exemplarLabels["uuid"] = idx.UUID
+		}
+		if loc, ok := shardLocations[idx.Index]; ok {
+			exemplarLabels["shard"] = loc.Shard
+			exemplarLabels["node"] = loc.Node
+		}
+
+		d.observeWithExemplar(d.docsCountDistribution, d.lastClusterInfo.ClusterName, cnt, exemplarLabels)
+		if size, err := strconv.ParseFloat(idx.StoreSize, 64); err == nil {
+			d.observeWithExemplar(d.storeSizeDistribution, d.lastClusterInfo.ClusterName, size, exemplarLabels)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			d.docsCountDesc, prometheus.GaugeValue, cnt, idx.Index, d.lastClusterInfo.ClusterName,
+		)
 	}
-	d.metrics.Collect(ch)
+
+	d.docsCountDistribution.Collect(ch)
+	d.storeSizeDistribution.Collect(ch)
 }
@@ -0,0 +1,312 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus-community/elasticsearch_exporter/pkg/clusterinfo"
+)
+
+// ilmIndexExplain is the per-index entry of the /_ilm/explain response.
+type ilmIndexExplain struct {
+	Managed              bool    `json:"managed"`
+	Phase                string  `json:"phase"`
+	Action               string  `json:"action"`
+	Step                 string  `json:"step"`
+	ActionTimeMillis     float64 `json:"action_time_millis"`
+	FailedStepRetryCount float64 `json:"failed_step_retry_count"`
+}
+
+type ilmExplainResponse struct {
+	Indices map[string]ilmIndexExplain `json:"indices"`
+}
+
+// slmPolicyInvocation is the last_success/last_failure entry of an SLM
+// policy, as returned by /_slm/policy.
+type slmPolicyInvocation struct {
+	StartTimeMillis float64 `json:"start_time_millis"`
+}
+
+type slmPolicy struct {
+	LastSuccess *slmPolicyInvocation `json:"last_success"`
+	LastFailure *slmPolicyInvocation `json:"last_failure"`
+}
+
+type slmPolicyResponse map[string]slmPolicy
+
+// IndexLifecycle scrapes /_ilm/explain (and, optionally, /_slm/policy) to
+// expose *why* and *when* an index transitioned through ILM, complementing
+// the read_only_indices count already exposed by IndicesSettings. On OSS
+// clusters where ILM isn't available it degrades to a single
+// elasticsearch_ilm_enabled=0 gauge rather than failing the scrape.
+type IndexLifecycle struct {
+	logger          *slog.Logger
+	client          *http.Client
+	url             *url.URL
+	includeSLM      bool
+	clusterInfoCh   chan *clusterinfo.Response
+	lastClusterInfo *clusterinfo.Response
+
+	ilmEnabledDesc       *prometheus.Desc
+	indexPhaseDesc       *prometheus.Desc
+	indexActionAgeDesc   *prometheus.Desc
+	indexStepFailureDesc *prometheus.Desc
+	indexManagedDesc     *prometheus.Desc
+	slmLastSuccessDesc   *prometheus.Desc
+	slmLastFailureDesc   *prometheus.Desc
+
+	jsonParseFailures prometheus.Counter
+
+	mu                sync.Mutex
+	stepFailureStates map[string]*stepFailureState // keyed by index+"\xff"+step
+}
+
+// stepFailureState tracks the last raw failed_step_retry_count seen for an
+// index/step pair so repeated observations of the same streak aren't
+// double-counted, alongside the running total exposed as a counter.
+type stepFailureState struct {
+	lastRetryCount float64
+	total          float64
+}
+
+// NewIndexLifecycle defines Index Lifecycle Management Prometheus metrics.
+// includeSLM additionally scrapes Snapshot Lifecycle Management policies,
+// which live under a separate API and aren't needed by every deployment.
+func NewIndexLifecycle(logger *slog.Logger, client *http.Client, url *url.URL, includeSLM bool) *IndexLifecycle {
+	il := &IndexLifecycle{
+		logger:     logger,
+		client:     client,
+		url:        url,
+		includeSLM: includeSLM,
+
+		ilmEnabledDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm", "enabled"),
+			"Whether Index Lifecycle Management is available on this cluster.",
+			[]string{"cluster"}, nil,
+		),
+		indexPhaseDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "phase"),
+			"Current ILM phase of an index (value is always 1).",
+			[]string{"index", "phase", "cluster"}, nil,
+		),
+		indexActionAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "action_age_seconds"),
+			"Time since the index entered its current ILM action.",
+			[]string{"index", "cluster"}, nil,
+		),
+		indexStepFailureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "step_failures_total"),
+			"Total number of ILM step execution failures observed for an index.",
+			[]string{"index", "step", "cluster"}, nil,
+		),
+		indexManagedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "managed"),
+			"Whether an index is managed by Index Lifecycle Management.",
+			[]string{"index", "cluster"}, nil,
+		),
+		slmLastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "last_success_timestamp_seconds"),
+			"Timestamp of the last successful execution of a Snapshot Lifecycle Management policy.",
+			[]string{"policy", "cluster"}, nil,
+		),
+		slmLastFailureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "last_failure_timestamp_seconds"),
+			"Timestamp of the last failed execution of a Snapshot Lifecycle Management policy.",
+			[]string{"policy", "cluster"}, nil,
+		),
+		jsonParseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(namespace, "ilm", "json_parse_failures"),
+			Help: "Number of JSON parse failures while collecting ILM/SLM stats.",
+		}),
+		clusterInfoCh: make(chan *clusterinfo.Response),
+		lastClusterInfo: &clusterinfo.Response{
+			ClusterName: "unknown_cluster",
+		},
+		stepFailureStates: make(map[string]*stepFailureState),
+	}
+
+	go func() {
+		logger.Debug("starting cluster info receive loop")
+		for ci := range il.clusterInfoCh {
+			if ci != nil {
+				logger.Debug("received cluster info update", "cluster", ci.ClusterName)
+				il.lastClusterInfo = ci
+			}
+		}
+		logger.Debug("exiting cluster info receive loop")
+	}()
+
+	return il
+}
+
+func (il *IndexLifecycle) ClusterLabelUpdates() *chan *clusterinfo.Response {
+	return &il.clusterInfoCh
+}
+
+func (il *IndexLifecycle) String() string {
+	return namespace + "_ilm"
+}
+
+func (il *IndexLifecycle) Describe(ch chan<- *prometheus.Desc) {
+	ch <- il.ilmEnabledDesc
+	ch <- il.indexPhaseDesc
+	ch <- il.indexActionAgeDesc
+	ch <- il.indexStepFailureDesc
+	ch <- il.indexManagedDesc
+	ch <- il.slmLastSuccessDesc
+	ch <- il.slmLastFailureDesc
+	ch <- il.jsonParseFailures.Desc()
+}
+
+func (il *IndexLifecycle) fetchILMExplain() (ilmExplainResponse, error) {
+	u := *il.url
+	u.Path = path.Join(u.Path, "/_ilm/explain")
+	q := u.Query()
+	q.Set("only_errors", "false")
+	u.RawQuery = q.Encode()
+
+	resp, err := il.client.Get(u.String())
+	if err != nil {
+		return ilmExplainResponse{}, fmt.Errorf("failed to fetch ILM explain: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// OSS clusters, and clusters with ILM disabled, answer with a 400/404
+	// here rather than an empty body; treat that as "ILM unavailable" rather
+	// than a scrape failure.
+	if resp.StatusCode != http.StatusOK {
+		return ilmExplainResponse{}, nil
+	}
+
+	var explain ilmExplainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&explain); err != nil {
+		return ilmExplainResponse{}, fmt.Errorf("failed to parse ILM explain JSON: %w", err)
+	}
+	return explain, nil
+}
+
+func (il *IndexLifecycle) fetchSLMPolicies() (slmPolicyResponse, error) {
+	u := *il.url
+	u.Path = path.Join(u.Path, "/_slm/policy")
+
+	resp, err := il.client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SLM policies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+
+	var policies slmPolicyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&policies); err != nil {
+		return nil, fmt.Errorf("failed to parse SLM policy JSON: %w", err)
+	}
+	return policies, nil
+}
+
+// stepFailureTotal turns the explain response's per-index
+// failed_step_retry_count, which resets to zero whenever a step stops
+// failing, into a monotonically increasing total suitable for a Prometheus
+// counter.
+func (il *IndexLifecycle) stepFailureTotal(index, step string, retryCount float64) float64 {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+
+	key := index + "\xff" + step
+	state, ok := il.stepFailureStates[key]
+	if !ok {
+		state = &stepFailureState{}
+		il.stepFailureStates[key] = state
+	}
+
+	switch {
+	case retryCount >= state.lastRetryCount:
+		// Includes the steady-state case (retryCount == lastRetryCount),
+		// which must add a zero delta rather than the full count again.
+		state.total += retryCount - state.lastRetryCount
+	case retryCount > 0:
+		// The step recovered and is now failing again from scratch.
+		state.total += retryCount
+	}
+	state.lastRetryCount = retryCount
+
+	return state.total
+}
+
+func (il *IndexLifecycle) Collect(ch chan<- prometheus.Metric) {
+	defer func() {
+		ch <- il.jsonParseFailures
+	}()
+
+	explain, err := il.fetchILMExplain()
+	if err != nil {
+		il.logger.Warn("failed to fetch ILM explain", "err", err)
+		il.jsonParseFailures.Inc()
+		return
+	}
+
+	cluster := il.lastClusterInfo.ClusterName
+
+	if explain.Indices == nil {
+		ch <- prometheus.MustNewConstMetric(il.ilmEnabledDesc, prometheus.GaugeValue, 0, cluster)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(il.ilmEnabledDesc, prometheus.GaugeValue, 1, cluster)
+
+	now := time.Now()
+	for index, ie := range explain.Indices {
+		managed := 0.0
+		if ie.Managed {
+			managed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(il.indexManagedDesc, prometheus.GaugeValue, managed, index, cluster)
+
+		if !ie.Managed {
+			continue
+		}
+
+		if ie.Phase != "" {
+			ch <- prometheus.MustNewConstMetric(il.indexPhaseDesc, prometheus.GaugeValue, 1, index, ie.Phase, cluster)
+		}
+		if ie.ActionTimeMillis > 0 {
+			age := now.Sub(time.UnixMilli(int64(ie.ActionTimeMillis))).Seconds()
+			ch <- prometheus.MustNewConstMetric(il.indexActionAgeDesc, prometheus.GaugeValue, age, index, cluster)
+		}
+		if ie.Step != "" {
+			total := il.stepFailureTotal(index, ie.Step, ie.FailedStepRetryCount)
+			ch <- prometheus.MustNewConstMetric(il.indexStepFailureDesc, prometheus.CounterValue, total, index, ie.Step, cluster)
+		}
+	}
+
+	if !il.includeSLM {
+		return
+	}
+
+	policies, err := il.fetchSLMPolicies()
+	if err != nil {
+		il.logger.Warn("failed to fetch SLM policies", "err", err)
+		il.jsonParseFailures.Inc()
+		return
+	}
+	for name, policy := range policies {
+		if policy.LastSuccess != nil {
+			ch <- prometheus.MustNewConstMetric(
+				il.slmLastSuccessDesc, prometheus.GaugeValue, policy.LastSuccess.StartTimeMillis/1000, name, cluster,
+			)
+		}
+		if policy.LastFailure != nil {
+			ch <- prometheus.MustNewConstMetric(
+				il.slmLastFailureDesc, prometheus.GaugeValue, policy.LastFailure.StartTimeMillis/1000, name, cluster,
+			)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"path"
 	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -34,6 +35,14 @@ type IndicesSettings struct {
 
 	readOnlyIndices prometheus.Gauge
 
+	// totalFieldsDistribution is a native histogram sibling of the
+	// total_fields gauge below, which carries no exemplar: one on a gauge
+	// would be dropped at exposition (OpenMetrics only carries exemplars on counter and
+	// histogram-bucket samples), so this is where an index's UUID actually
+	// reaches the scraper, letting users jump from a skewed distribution to
+	// the specific index responsible for it.
+	totalFieldsDistribution prometheus.Histogram
+
 	metrics []*indicesSettingsMetric
 }
 
@@ -47,11 +56,16 @@ type indicesSettingsMetric struct {
 	Type  prometheus.ValueType
 	Desc  *prometheus.Desc
 	Value func(indexSettings Settings) float64
+
+	// Observe, when set, additionally records Value's result (with a uuid
+	// exemplar when known) onto a native histogram of that setting's
+	// distribution across the cluster.
+	Observe func(value float64, uuid string)
 }
 
 // NewIndicesSettings defines Indices Settings Prometheus metrics
 func NewIndicesSettings(logger *slog.Logger, client *http.Client, url *url.URL) *IndicesSettings {
-	return &IndicesSettings{
+	cs := &IndicesSettings{
 		logger: logger,
 		client: client,
 		url:    url,
@@ -61,59 +75,82 @@ func NewIndicesSettings(logger *slog.Logger, client *http.Client, url *url.URL)
 			Help: "Current number of read only indices within cluster",
 		}),
 
-		metrics: []*indicesSettingsMetric{
-			{
-				Type: prometheus.GaugeValue,
-				Desc: prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "indices_settings", "total_fields"),
-					"index mapping setting for total_fields",
-					defaultIndicesTotalFieldsLabels, nil,
-				),
-				Value: func(indexSettings Settings) float64 {
-					val, err := strconv.ParseFloat(indexSettings.IndexInfo.Mapping.TotalFields.Limit, 64)
-					if err != nil {
-						return float64(defaultTotalFieldsValue)
-					}
-					return val
-				},
+		totalFieldsDistribution: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:                            prometheus.BuildFQName(namespace, "indices_settings", "total_fields_distribution"),
+			Help:                            "Native histogram of the per-index total_fields mapping setting across the cluster.",
+			Buckets:                         []float64{},
+			NativeHistogramBucketFactor:     1.1,
+			NativeHistogramMaxBucketNumber:  160,
+			NativeHistogramMinResetDuration: time.Hour,
+		}),
+	}
+
+	cs.metrics = []*indicesSettingsMetric{
+		{
+			Type: prometheus.GaugeValue,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "indices_settings", "total_fields"),
+				"index mapping setting for total_fields",
+				defaultIndicesTotalFieldsLabels, nil,
+			),
+			Value: func(indexSettings Settings) float64 {
+				val, err := strconv.ParseFloat(indexSettings.IndexInfo.Mapping.TotalFields.Limit, 64)
+				if err != nil {
+					return float64(defaultTotalFieldsValue)
+				}
+				return val
 			},
-			{
-				Type: prometheus.GaugeValue,
-				Desc: prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "indices_settings", "replicas"),
-					"index setting number_of_replicas",
-					defaultIndicesTotalFieldsLabels, nil,
-				),
-				Value: func(indexSettings Settings) float64 {
-					val, err := strconv.ParseFloat(indexSettings.IndexInfo.NumberOfReplicas, 64)
-					if err != nil {
-						return float64(defaultTotalFieldsValue)
-					}
-					return val
-				},
+			Observe: cs.observeTotalFields,
+		},
+		{
+			Type: prometheus.GaugeValue,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "indices_settings", "replicas"),
+				"index setting number_of_replicas",
+				defaultIndicesTotalFieldsLabels, nil,
+			),
+			Value: func(indexSettings Settings) float64 {
+				val, err := strconv.ParseFloat(indexSettings.IndexInfo.NumberOfReplicas, 64)
+				if err != nil {
+					return float64(defaultTotalFieldsValue)
+				}
+				return val
 			},
-			{
-				Type: prometheus.GaugeValue,
-				Desc: prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "indices_settings", "creation_timestamp_seconds"),
-					"index setting creation_date",
-					defaultIndicesTotalFieldsLabels, nil,
-				),
-				Value: func(indexSettings Settings) float64 {
-					val, err := strconv.ParseFloat(indexSettings.IndexInfo.CreationDate, 64)
-					if err != nil {
-						return float64(defaultDateCreation)
-					}
-					return val / 1000.0
-				},
+		},
+		{
+			Type: prometheus.GaugeValue,
+			Desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "indices_settings", "creation_timestamp_seconds"),
+				"index setting creation_date",
+				defaultIndicesTotalFieldsLabels, nil,
+			),
+			Value: func(indexSettings Settings) float64 {
+				val, err := strconv.ParseFloat(indexSettings.IndexInfo.CreationDate, 64)
+				if err != nil {
+					return float64(defaultDateCreation)
+				}
+				return val / 1000.0
 			},
 		},
 	}
+
+	return cs
+}
+
+// observeTotalFields records value on totalFieldsDistribution, attaching uuid
+// as an exemplar when known.
+func (cs *IndicesSettings) observeTotalFields(value float64, uuid string) {
+	if uuid == "" {
+		cs.totalFieldsDistribution.Observe(value)
+		return
+	}
+	cs.totalFieldsDistribution.(prometheus.ExemplarObserver).ObserveWithExemplar(value, prometheus.Labels{"uuid": uuid})
 }
 
 // Describe add Snapshots metrics descriptions
 func (cs *IndicesSettings) Describe(ch chan<- *prometheus.Desc) {
 	ch <- cs.readOnlyIndices.Desc()
+	ch <- cs.totalFieldsDistribution.Desc()
 
 	for _, metric := range cs.metrics {
 		ch <- metric.Desc
@@ -164,6 +201,32 @@ func (cs *IndicesSettings) fetchAndDecodeIndicesSettings() (IndicesSettingsRespo
 	return asr, err
 }
 
+// fetchIndexUUIDs maps index name to index UUID via /_cat/indices, so the
+// total_fields_distribution histogram can attach a per-observation exemplar
+// pointing at the index responsible for it.
+func (cs *IndicesSettings) fetchIndexUUIDs() (map[string]string, error) {
+	u := *cs.url
+	u.Path = path.Join(u.Path, "/_cat/indices")
+	q := u.Query()
+	q.Set("format", "json")
+	q.Set("h", "index,uuid")
+	u.RawQuery = q.Encode()
+
+	var rows []struct {
+		Index string `json:"index"`
+		UUID  string `json:"uuid"`
+	}
+	if err := cs.getAndParseURL(&u, &rows); err != nil {
+		return nil, err
+	}
+
+	uuids := make(map[string]string, len(rows))
+	for _, row := range rows {
+		uuids[row.Index] = row.UUID
+	}
+	return uuids, nil
+}
+
 // Collect gets all indices settings metric values
 func (cs *IndicesSettings) Collect(ch chan<- prometheus.Metric) {
 	asr, err := cs.fetchAndDecodeIndicesSettings()
@@ -176,21 +239,32 @@ func (cs *IndicesSettings) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	uuids, err := cs.fetchIndexUUIDs()
+	if err != nil {
+		cs.logger.Warn("failed to fetch index UUIDs, exemplars will be omitted", "err", err)
+		uuids = nil
+	}
+
 	var c int
 	for indexName, value := range asr {
 		if value.Settings.IndexInfo.Blocks.ReadOnly == "true" {
 			c++
 		}
 		for _, metric := range cs.metrics {
+			val := metric.Value(value.Settings)
 			ch <- prometheus.MustNewConstMetric(
 				metric.Desc,
 				metric.Type,
-				metric.Value(value.Settings),
+				val,
 				indexName,
 			)
+			if metric.Observe != nil {
+				metric.Observe(val, uuids[indexName])
+			}
 		}
 	}
 	cs.readOnlyIndices.Set(float64(c))
 
 	ch <- cs.readOnlyIndices
+	ch <- cs.totalFieldsDistribution
 }
@@ -0,0 +1,143 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config defines the schema for -config.file, which lets a single
+// exporter process fan out across several Elasticsearch clusters instead of
+// the one-cluster-per-flag-set model, and provides the Manager that turns a
+// parsed Config into one http.Client/*prometheus.Registry pair per cluster,
+// served under /probe?target=<name> in blackbox-exporter style. The
+// exporter's main command is still the one that supplies
+// ClusterRegistryBuilder (it alone knows how to build this repo's
+// collectors) and decides what to serve at /metrics when no -config.file is
+// set; Manager.Active reports when that flag-only fallback applies.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the top-level -config.file document.
+type Config struct {
+	Clusters []ClusterConfig `yaml:"clusters"`
+}
+
+// ClusterConfig describes one Elasticsearch cluster to scrape and the
+// credentials/collectors to use against it.
+type ClusterConfig struct {
+	Name     string    `yaml:"name"`
+	URL      string    `yaml:"url"`
+	Username string    `yaml:"username,omitempty"`
+	Password string    `yaml:"password,omitempty"`
+	APIKey   string    `yaml:"api_key,omitempty"`
+	TLS      TLSConfig `yaml:"tls,omitempty"`
+
+	Collectors CollectorsConfig `yaml:"collectors,omitempty"`
+}
+
+// TLSConfig mirrors the handful of client_golang/config_util TLS knobs that
+// matter for talking to an Elasticsearch cluster over HTTPS.
+type TLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+}
+
+// CollectorsConfig toggles individual collectors for a cluster. A nil entry
+// means "use the exporter's default for this collector"; an explicit
+// Enabled: false turns it off for that cluster only.
+type CollectorsConfig struct {
+	DocsCount       *DocsCountCollectorConfig `yaml:"docs_count,omitempty"`
+	IndicesSettings *CollectorConfig          `yaml:"indices_settings,omitempty"`
+	ILM             *ILMCollectorConfig       `yaml:"ilm,omitempty"`
+}
+
+// CollectorConfig is the shared shape for collectors that take no further
+// configuration beyond whether they run at all.
+type CollectorConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// IndexPatternConfig is embedded by collectors that filter indices, mirroring
+// the literal/glob/regex pattern semantics of DocsCount's
+// -es.docs-count.indices / -es.docs-count.exclude-indices flags.
+type IndexPatternConfig struct {
+	IncludedIndices []string `yaml:"included_indices,omitempty"`
+	ExcludedIndices []string `yaml:"excluded_indices,omitempty"`
+}
+
+// DocsCountCollectorConfig is the docs_count entry of a cluster's collectors
+// block.
+type DocsCountCollectorConfig struct {
+	Enabled            bool `yaml:"enabled"`
+	IndexPatternConfig `yaml:",inline"`
+}
+
+// ILMCollectorConfig is the ilm entry of a cluster's collectors block.
+// IncludeSLM additionally scrapes Snapshot Lifecycle Management policies.
+type ILMCollectorConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	IncludeSLM bool `yaml:"include_slm,omitempty"`
+}
+
+// Load reads and validates a -config.file document. Callers should treat a
+// missing file as "fall back to flag-only, single-cluster behavior" rather
+// than calling Load at all; Load itself always requires the file to exist.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	seen := make(map[string]bool, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster entry missing required field %q", "name")
+		}
+		if cluster.URL == "" {
+			return fmt.Errorf("cluster %q missing required field %q", cluster.Name, "url")
+		}
+		if seen[cluster.Name] {
+			return fmt.Errorf("duplicate cluster name %q", cluster.Name)
+		}
+		seen[cluster.Name] = true
+	}
+	return nil
+}
+
+// ClusterByName returns the cluster entry with the given name, for use by
+// the /probe?target=<name> handler.
+func (c *Config) ClusterByName(name string) (*ClusterConfig, bool) {
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == name {
+			return &c.Clusters[i], true
+		}
+	}
+	return nil, false
+}
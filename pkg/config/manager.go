@@ -0,0 +1,206 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ClusterRegistryBuilder builds the *prometheus.Registry holding a single
+// cluster's configured collectors, against the http.Client Manager has
+// already built for it (TLS and username/password/api_key applied).
+// Exporter main wires this up with whatever constructs its collector set.
+type ClusterRegistryBuilder func(cluster ClusterConfig, client *http.Client) (*prometheus.Registry, error)
+
+type clusterRuntime struct {
+	client   *http.Client
+	registry *prometheus.Registry
+}
+
+// Manager owns one http.Client and one *prometheus.Registry per cluster
+// declared in a -config.file, and serves them under /probe?target=<name> in
+// blackbox-exporter style so a single exporter process can fan out across
+// many clusters. Reload rebuilds every cluster in place, so a SIGHUP (wired
+// up via WatchReloadSignal) picks up edits without dropping whatever
+// http.Server is calling ServeProbe.
+type Manager struct {
+	build ClusterRegistryBuilder
+
+	mu       sync.RWMutex
+	clusters map[string]*clusterRuntime
+}
+
+// NewManager returns a Manager with no clusters loaded. Until Reload is
+// called with a non-empty path, Active reports false and callers should
+// keep serving their own flag-configured, single-cluster registry.
+func NewManager(build ClusterRegistryBuilder) *Manager {
+	return &Manager{build: build, clusters: make(map[string]*clusterRuntime)}
+}
+
+// Reload parses path and rebuilds every cluster's http.Client and
+// *prometheus.Registry. On error, the previously loaded clusters are left
+// untouched and keep serving traffic. An empty path clears all clusters,
+// which is how callers fall back to flag-only behavior when -config.file
+// isn't set.
+func (m *Manager) Reload(path string) error {
+	if path == "" {
+		m.mu.Lock()
+		m.clusters = make(map[string]*clusterRuntime)
+		m.mu.Unlock()
+		return nil
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	next := make(map[string]*clusterRuntime, len(cfg.Clusters))
+	for _, cluster := range cfg.Clusters {
+		client, err := NewHTTPClient(cluster)
+		if err != nil {
+			return fmt.Errorf("cluster %q: %w", cluster.Name, err)
+		}
+		registry, err := m.build(cluster, client)
+		if err != nil {
+			return fmt.Errorf("cluster %q: %w", cluster.Name, err)
+		}
+		next[cluster.Name] = &clusterRuntime{client: client, registry: registry}
+	}
+
+	m.mu.Lock()
+	m.clusters = next
+	m.mu.Unlock()
+	return nil
+}
+
+// Active reports whether any cluster from -config.file is currently loaded.
+func (m *Manager) Active() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.clusters) > 0
+}
+
+// ServeProbe implements /probe?target=<name>: it looks up the named
+// cluster's registry and renders it exactly as promhttp would for a normal
+// /metrics endpoint.
+func (m *Manager) ServeProbe(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.RLock()
+	cr, ok := m.clusters[target]
+	m.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown target %q", target), http.StatusNotFound)
+		return
+	}
+
+	promhttp.HandlerFor(cr.registry, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, r)
+}
+
+// WatchReloadSignal reloads path into m whenever the process receives
+// SIGHUP, logging rather than exiting on failure so a bad edit to the
+// config file doesn't take down an already-running exporter.
+func WatchReloadSignal(m *Manager, path string, logger *slog.Logger) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			if err := m.Reload(path); err != nil {
+				logger.Error("failed to reload config on SIGHUP", "path", path, "err", err)
+				continue
+			}
+			logger.Info("reloaded config on SIGHUP", "path", path)
+		}
+	}()
+}
+
+// authRoundTripper attaches a cluster's configured credentials to every
+// outgoing request before handing it to the underlying transport.
+type authRoundTripper struct {
+	next     http.RoundTripper
+	username string
+	password string
+	apiKey   string
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	switch {
+	case rt.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+rt.apiKey)
+	case rt.username != "":
+		req.SetBasicAuth(rt.username, rt.password)
+	}
+	return rt.next.RoundTrip(req)
+}
+
+// NewHTTPClient builds the http.Client used to scrape a single cluster,
+// applying its username/password or api_key and TLS settings.
+func NewHTTPClient(cluster ClusterConfig) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(cluster.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build TLS config: %w", err)
+	}
+
+	return &http.Client{
+		Transport: &authRoundTripper{
+			next:     &http.Transport{TLSClientConfig: tlsConfig},
+			username: cluster.Username,
+			password: cluster.Password,
+			apiKey:   cluster.APIKey,
+		},
+	}, nil
+}
+
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in ca_file %q", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}